@@ -0,0 +1,18 @@
+package datastore
+
+import "go.mercari.io/datastore/internal"
+
+type withMaxBatchSize int
+
+func (w withMaxBatchSize) Apply(s *internal.ClientSettings) {
+	s.MaxBatchSize = int(w)
+}
+
+// WithMaxBatchSize returns a ClientOption that overrides the number of
+// entities the backend sends to Cloud Datastore in a single
+// PutMulti/GetMulti/DeleteMulti RPC. Calls with more entities than this are
+// split into sub-batches transparently. Defaults to 500, Cloud Datastore's
+// own per-call limit.
+func WithMaxBatchSize(size int) ClientOption {
+	return withMaxBatchSize(size)
+}
@@ -0,0 +1,96 @@
+package datastore
+
+import (
+	"net/http"
+
+	"go.mercari.io/datastore/internal"
+	"golang.org/x/oauth2"
+)
+
+// ClientOption configures the behavior of FromContext. Each backend package
+// translates the resulting internal.ClientSettings into its own client
+// construction call.
+type ClientOption interface {
+	Apply(s *internal.ClientSettings)
+}
+
+type withScopes []string
+
+func (w withScopes) Apply(s *internal.ClientSettings) {
+	s.Scopes = []string(w)
+}
+
+// WithScopes returns a ClientOption that overrides the default OAuth2 scopes
+// used to authenticate the underlying client.
+func WithScopes(scopes ...string) ClientOption {
+	return withScopes(scopes)
+}
+
+type withTokenSource struct {
+	ts oauth2.TokenSource
+}
+
+func (w *withTokenSource) Apply(s *internal.ClientSettings) {
+	s.TokenSource = w.ts
+}
+
+// WithTokenSource returns a ClientOption that specifies the token source to
+// authenticate the underlying client.
+func WithTokenSource(ts oauth2.TokenSource) ClientOption {
+	return &withTokenSource{ts: ts}
+}
+
+type withCredentialsFile string
+
+func (w withCredentialsFile) Apply(s *internal.ClientSettings) {
+	s.CredentialsFile = string(w)
+}
+
+// WithCredentialsFile returns a ClientOption that specifies a service account
+// or refresh token JSON credentials file to authenticate the underlying
+// client.
+func WithCredentialsFile(file string) ClientOption {
+	return withCredentialsFile(file)
+}
+
+type withHTTPClient struct {
+	client *http.Client
+}
+
+func (w *withHTTPClient) Apply(s *internal.ClientSettings) {
+	s.HTTPClient = w.client
+}
+
+// WithHTTPClient returns a ClientOption that specifies the HTTP client to use
+// as the basis of communication with the underlying client.
+func WithHTTPClient(client *http.Client) ClientOption {
+	return &withHTTPClient{client: client}
+}
+
+type withDatabaseID string
+
+func (w withDatabaseID) Apply(s *internal.ClientSettings) {
+	s.DatabaseID = string(w)
+}
+
+// WithDatabaseID returns a ClientOption that selects a Firestore in Datastore
+// mode database other than the default one. Pass the database ID as shown in
+// the Cloud console; leave it unset to keep using the default `(default)`
+// database.
+func WithDatabaseID(databaseID string) ClientOption {
+	return withDatabaseID(databaseID)
+}
+
+type withEmulator string
+
+func (w withEmulator) Apply(s *internal.ClientSettings) {
+	s.EmulatorHost = string(w)
+}
+
+// WithEmulator returns a ClientOption that routes the underlying client at a
+// local Datastore/Firestore emulator instead of the production service, e.g.
+// "localhost:8081". This is equivalent to setting DATASTORE_EMULATOR_HOST but
+// scoped to a single client instead of the whole process.
+func WithEmulator(host string) ClientOption {
+	return withEmulator(host)
+}
@@ -0,0 +1,39 @@
+package datastore
+
+import (
+	"go.mercari.io/datastore/internal"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type withTracerProvider struct {
+	tp trace.TracerProvider
+}
+
+func (w *withTracerProvider) Apply(s *internal.ClientSettings) {
+	s.TracerProvider = w.tp
+}
+
+// WithTracerProvider returns a ClientOption that installs the
+// trace.TracerProvider the backend uses to create spans for PutMulti,
+// GetMulti, DeleteMulti, Run, GetAll, Next, Commit and Rollback. Defaults to
+// the global provider registered with otel.SetTracerProvider.
+func WithTracerProvider(tp trace.TracerProvider) ClientOption {
+	return &withTracerProvider{tp: tp}
+}
+
+type withMeterProvider struct {
+	mp metric.MeterProvider
+}
+
+func (w *withMeterProvider) Apply(s *internal.ClientSettings) {
+	s.MeterProvider = w.mp
+}
+
+// WithMeterProvider returns a ClientOption that installs the
+// metric.MeterProvider the backend uses to record latency and batch size
+// measurements. Defaults to the global provider registered with
+// otel.SetMeterProvider.
+func WithMeterProvider(mp metric.MeterProvider) ClientOption {
+	return &withMeterProvider{mp: mp}
+}
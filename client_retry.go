@@ -0,0 +1,74 @@
+package datastore
+
+import (
+	"math/rand"
+	"time"
+
+	"go.mercari.io/datastore/internal"
+)
+
+// RetryPolicy decides whether a failed PutMulti/GetMulti/DeleteMulti call
+// should be retried. See internal.RetryPolicy for the interface backends
+// consult.
+type RetryPolicy = internal.RetryPolicy
+
+type withRetryPolicy struct {
+	policy RetryPolicy
+}
+
+func (w *withRetryPolicy) Apply(s *internal.ClientSettings) {
+	s.RetryPolicy = w.policy
+}
+
+// WithRetryPolicy returns a ClientOption that installs a RetryPolicy used by
+// the backend to retry transient failures (contention aborts,
+// deadline-exceeded, unavailable) on multi-entity operations.
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return &withRetryPolicy{policy: policy}
+}
+
+// ExponentialBackoffRetryPolicy is a jittered, capped exponential backoff
+// implementation of RetryPolicy.
+type ExponentialBackoffRetryPolicy struct {
+	// MaxAttempts is the maximum number of retries performed, not counting
+	// the initial attempt.
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry.
+	BaseDelay time.Duration
+	// MaxDelay caps the computed backoff before jitter is applied, so a
+	// returned backoff can exceed it by up to 20%.
+	MaxDelay time.Duration
+}
+
+// NewExponentialBackoffRetryPolicy returns an ExponentialBackoffRetryPolicy
+// with reasonable defaults: up to 3 retries, starting at 100ms and capped at
+// 2s, with +/-20% jitter.
+func NewExponentialBackoffRetryPolicy() *ExponentialBackoffRetryPolicy {
+	return &ExponentialBackoffRetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   100 * time.Millisecond,
+		MaxDelay:    2 * time.Second,
+	}
+}
+
+// NextBackoff implements RetryPolicy.
+func (p *ExponentialBackoffRetryPolicy) NextBackoff(attempt int, err error) (time.Duration, bool) {
+	if attempt > p.MaxAttempts {
+		return 0, false
+	}
+
+	delay := p.BaseDelay << uint(attempt-1)
+	if delay > p.MaxDelay || delay <= 0 {
+		delay = p.MaxDelay
+	}
+
+	// +/-20% jitter: sample uniformly from a spread of 40% of delay, then
+	// shift down by half the spread so the result is centered on delay
+	// instead of only ever adding to it.
+	spread := int64(delay) * 2 / 5
+	if spread <= 0 {
+		return delay, true
+	}
+	jitter := time.Duration(rand.Int63n(spread)) - time.Duration(spread)/2
+	return delay + jitter, true
+}
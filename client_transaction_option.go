@@ -0,0 +1,56 @@
+package datastore
+
+import "time"
+
+// TransactionSettings collects the TransactionOption values passed to
+// Client.RunInTransaction. Backend packages read it the same way they read
+// internal.ClientSettings for ClientOption.
+type TransactionSettings struct {
+	ReadOnly bool
+	ReadTime time.Time
+}
+
+// TransactionOption configures a transaction started through
+// Client.RunInTransaction.
+type TransactionOption interface {
+	Apply(s *TransactionSettings)
+}
+
+// NewTransactionSettings builds a TransactionSettings from opts.
+func NewTransactionSettings(opts ...TransactionOption) *TransactionSettings {
+	settings := &TransactionSettings{}
+	for _, opt := range opts {
+		opt.Apply(settings)
+	}
+	return settings
+}
+
+type withReadOnly struct{}
+
+func (withReadOnly) Apply(s *TransactionSettings) {
+	s.ReadOnly = true
+}
+
+// WithReadOnly returns a TransactionOption that opens a read-only
+// transaction. Read-only transactions never contend with concurrent writers
+// and their Commit/Rollback are no-ops.
+func WithReadOnly() TransactionOption {
+	return withReadOnly{}
+}
+
+type withReadTime struct {
+	t time.Time
+}
+
+func (w withReadTime) Apply(s *TransactionSettings) {
+	s.ReadTime = w.t
+	s.ReadOnly = true
+}
+
+// WithReadTime returns a TransactionOption that opens a read-only
+// transaction pinned to a specific point in time, for consistent
+// multi-entity reads (e.g. exports/backups) without paying RW contention
+// costs.
+func WithReadTime(t time.Time) TransactionOption {
+	return withReadTime{t: t}
+}
@@ -0,0 +1,118 @@
+package clouddatastore
+
+import (
+	"sync"
+
+	"cloud.google.com/go/datastore"
+)
+
+// defaultMaxBatchSize mirrors Cloud Datastore's own per-call limit on
+// PutMulti/GetMulti/DeleteMulti.
+const defaultMaxBatchSize = 500
+
+// maxBatchSize returns the configured MaxBatchSize for d's client, falling
+// back to defaultMaxBatchSize.
+func (d *datastoreImpl) maxBatchSize() int {
+	if d.settings == nil || d.settings.MaxBatchSize <= 0 {
+		return defaultMaxBatchSize
+	}
+	return d.settings.MaxBatchSize
+}
+
+// batchRanges splits [0, n) into chunks of at most size entries each,
+// preserving order.
+func batchRanges(n, size int) [][2]int {
+	if size <= 0 || n <= size {
+		return [][2]int{{0, n}}
+	}
+
+	ranges := make([][2]int, 0, (n+size-1)/size)
+	for start := 0; start < n; start += size {
+		end := start + size
+		if end > n {
+			end = n
+		}
+		ranges = append(ranges, [2]int{start, end})
+	}
+	return ranges
+}
+
+// mergeBatchErrors combines the per-batch errors produced by running
+// batchRanges(n, size) sub-calls back into a single error addressed by the
+// original, unsplit indices. A nil result means every sub-batch succeeded.
+// When the call was never split (len(ranges) == 1, the common case), the
+// single sub-call's error -- whatever its type -- is returned unchanged, so
+// callers that aren't hitting the 500-entity limit see exactly the error
+// cloud.google.com/go/datastore would have returned before batching existed.
+// Only once a call actually gets split do we synthesize a datastore.MultiError
+// of length n to address errors back to their original indices.
+func mergeBatchErrors(n int, ranges [][2]int, errs []error) error {
+	if len(ranges) == 1 {
+		return errs[0]
+	}
+
+	var merr datastore.MultiError
+	for i, r := range ranges {
+		err := errs[i]
+		if err == nil {
+			continue
+		}
+
+		if merr == nil {
+			merr = make(datastore.MultiError, n)
+		}
+
+		if sub, ok := err.(datastore.MultiError); ok {
+			for j, e := range sub {
+				merr[r[0]+j] = e
+			}
+			continue
+		}
+
+		for idx := r[0]; idx < r[1]; idx++ {
+			merr[idx] = err
+		}
+	}
+
+	if merr == nil {
+		return nil
+	}
+	return merr
+}
+
+// runBatchesConcurrently runs fn once per range returned by batchRanges in
+// parallel and collects the resulting errors in range order. Used by the
+// client bridge, where sub-batches are independent RPCs.
+func runBatchesConcurrently(ranges [][2]int, fn func(start, end int) error) []error {
+	errs := make([]error, len(ranges))
+	if len(ranges) == 1 {
+		errs[0] = fn(ranges[0][0], ranges[0][1])
+		return errs
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(len(ranges))
+	for i, r := range ranges {
+		go func(i int, r [2]int) {
+			defer wg.Done()
+			errs[i] = fn(r[0], r[1])
+		}(i, r)
+	}
+	wg.Wait()
+
+	return errs
+}
+
+// runBatchesSequentially runs fn once per range in order, stopping at the
+// first error. Used inside transactions, where sub-batches must execute on
+// the same baseTx to stay atomic.
+func runBatchesSequentially(ranges [][2]int, fn func(start, end int) error) []error {
+	errs := make([]error, len(ranges))
+	for i, r := range ranges {
+		errs[i] = fn(r[0], r[1])
+		if errs[i] != nil && len(ranges) > 1 {
+			break
+		}
+	}
+	return errs
+}
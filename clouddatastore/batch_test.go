@@ -0,0 +1,41 @@
+package clouddatastore
+
+import (
+	"errors"
+	"testing"
+
+	"cloud.google.com/go/datastore"
+)
+
+func TestMergeBatchErrors_SingleRangeReturnsUnderlyingErrorUnchanged(t *testing.T) {
+	scalarErr := errors.New("unavailable")
+	ranges := [][2]int{{0, 3}}
+
+	if got := mergeBatchErrors(3, ranges, []error{scalarErr}); got != scalarErr {
+		t.Fatalf("got %#v, want the original scalar error unchanged", got)
+	}
+
+	if got := mergeBatchErrors(3, ranges, []error{nil}); got != nil {
+		t.Fatalf("got %#v, want nil", got)
+	}
+}
+
+func TestMergeBatchErrors_SplitRangesAddressErrorsByOriginalIndex(t *testing.T) {
+	ranges := [][2]int{{0, 2}, {2, 4}}
+	batchErr := errors.New("batch failed")
+
+	merr, ok := mergeBatchErrors(4, ranges, []error{nil, batchErr}).(datastore.MultiError)
+	if !ok {
+		t.Fatalf("expected a datastore.MultiError once a call is split")
+	}
+	for i := 0; i < 2; i++ {
+		if merr[i] != nil {
+			t.Fatalf("index %d: got %v, want nil (first batch succeeded)", i, merr[i])
+		}
+	}
+	for i := 2; i < 4; i++ {
+		if merr[i] != batchErr {
+			t.Fatalf("index %d: got %v, want %v (second batch failed)", i, merr[i], batchErr)
+		}
+	}
+}
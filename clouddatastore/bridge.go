@@ -10,6 +10,7 @@ import (
 	"go.mercari.io/datastore/internal"
 	"go.mercari.io/datastore/internal/shared"
 	"google.golang.org/api/option"
+	"google.golang.org/grpc"
 )
 
 func init() {
@@ -51,13 +52,22 @@ func FromContext(ctx context.Context, opts ...w.ClientOption) (w.Client, error)
 	if settings.HTTPClient != nil {
 		origOpts = append(origOpts, option.WithHTTPClient(settings.HTTPClient))
 	}
+	if settings.EmulatorHost != "" {
+		origOpts = append(origOpts, option.WithEndpoint(settings.EmulatorHost), option.WithoutAuthentication(), option.WithGRPCDialOption(grpc.WithInsecure()))
+	}
 
-	client, err := datastore.NewClient(ctx, settings.ProjectID, origOpts...)
+	var client *datastore.Client
+	var err error
+	if settings.DatabaseID != "" {
+		client, err = datastore.NewClientWithDatabase(ctx, settings.ProjectID, settings.DatabaseID, origOpts...)
+	} else {
+		client, err = datastore.NewClient(ctx, settings.ProjectID, origOpts...)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	return &datastoreImpl{ctx: ctx, client: client}, nil
+	return &datastoreImpl{ctx: ctx, client: client, settings: settings}, nil
 }
 
 func IsCloudDatastoreClient(client w.Client) bool {
@@ -65,6 +75,18 @@ func IsCloudDatastoreClient(client w.Client) bool {
 	return ok
 }
 
+// hasIncompleteKey reports whether any key in keys has no ID/name assigned
+// yet (w.Key.Incomplete), i.e. Cloud Datastore would allocate a new ID for it
+// on Put.
+func hasIncompleteKey(keys []w.Key) bool {
+	for _, key := range keys {
+		if key.Incomplete() {
+			return true
+		}
+	}
+	return false
+}
+
 var _ shared.OriginalClientBridge = &originalClientBridgeImpl{}
 var _ shared.OriginalTransactionBridge = &originalTransactionBridgeImpl{}
 var _ shared.OriginalIteratorBridge = &originalIteratorBridgeImpl{}
@@ -73,48 +95,115 @@ type originalClientBridgeImpl struct {
 	d *datastoreImpl
 }
 
-func (ocb *originalClientBridgeImpl) PutMulti(ctx context.Context, keys []w.Key, psList []w.PropertyList) ([]w.Key, error) {
-	origKeys := toOriginalKeys(keys)
-	origPss := toOriginalPropertyListList(psList)
+func (ocb *originalClientBridgeImpl) PutMulti(ctx context.Context, keys []w.Key, psList []w.PropertyList) (_ []w.Key, err error) {
+	ctx, end := startSpan(ctx, ocb.d, "PutMulti", len(keys))
+	defer func() { end(err) }()
+
+	policy := retryPolicyFromContext(ctx, ocb.d.retryPolicy())
+	ranges := batchRanges(len(keys), ocb.d.maxBatchSize())
+
+	wKeys := make([]w.Key, len(keys))
+	errs := runBatchesConcurrently(ranges, func(start, stop int) error {
+		// Retrying a batch that contains an incomplete key is not safe: if the
+		// first attempt's Put actually succeeded server-side and only the
+		// response was lost to the transient error, Cloud Datastore would
+		// allocate a fresh ID on the retry and leave both the original and the
+		// retried entity behind. Only retry batches where every key already
+		// has an ID/name, so a retry reapplies to the exact same entities.
+		batchPolicy := policy
+		if hasIncompleteKey(keys[start:stop]) {
+			batchPolicy = nil
+		}
+
+		return withRetry(ctx, batchPolicy, func() error {
+			origKeys := toOriginalKeys(keys[start:stop])
+			origPss := toOriginalPropertyListList(psList[start:stop])
 
-	origKeys, err := ocb.d.client.PutMulti(ctx, origKeys, origPss)
-	if err != nil {
-		return nil, toWrapperError(err)
+			origKeys, err := ocb.d.client.PutMulti(ctx, origKeys, origPss)
+			if err != nil {
+				return err
+			}
+
+			copy(wKeys[start:stop], toWrapperKeys(origKeys))
+			return nil
+		})
+	})
+
+	if merr := mergeBatchErrors(len(keys), ranges, errs); merr != nil {
+		err = toWrapperError(merr)
+		return nil, err
 	}
 
-	return toWrapperKeys(origKeys), nil
+	return wKeys, nil
 }
 
-func (ocb *originalClientBridgeImpl) GetMulti(ctx context.Context, keys []w.Key, psList []w.PropertyList) error {
-	origKeys := toOriginalKeys(keys)
-	origPss := toOriginalPropertyListList(psList)
+func (ocb *originalClientBridgeImpl) GetMulti(ctx context.Context, keys []w.Key, psList []w.PropertyList) (err error) {
+	ctx, end := startSpan(ctx, ocb.d, "GetMulti", len(keys))
+	defer func() { end(err) }()
 
-	err := ocb.d.client.GetMulti(ctx, origKeys, origPss)
-	if err != nil {
-		return toWrapperError(err)
-	}
+	policy := retryPolicyFromContext(ctx, ocb.d.retryPolicy())
+	ranges := batchRanges(len(keys), ocb.d.maxBatchSize())
 
-	// TODO should be copy? not replace?
-	wPss := toWrapperPropertyListList(origPss)
-	for idx, wPs := range wPss {
-		psList[idx] = wPs
+	errs := runBatchesConcurrently(ranges, func(start, stop int) error {
+		return withRetry(ctx, policy, func() error {
+			origKeys := toOriginalKeys(keys[start:stop])
+			origPss := toOriginalPropertyListList(psList[start:stop])
+
+			err := ocb.d.client.GetMulti(ctx, origKeys, origPss)
+			if err != nil {
+				return err
+			}
+
+			// TODO should be copy? not replace?
+			wPss := toWrapperPropertyListList(origPss)
+			for i, wPs := range wPss {
+				psList[start+i] = wPs
+			}
+
+			return nil
+		})
+	})
+
+	if merr := mergeBatchErrors(len(keys), ranges, errs); merr != nil {
+		err = toWrapperError(merr)
+		return err
 	}
 
 	return nil
 }
 
-func (ocb *originalClientBridgeImpl) DeleteMulti(ctx context.Context, keys []w.Key) error {
-	origKeys := toOriginalKeys(keys)
+func (ocb *originalClientBridgeImpl) DeleteMulti(ctx context.Context, keys []w.Key) (err error) {
+	ctx, end := startSpan(ctx, ocb.d, "DeleteMulti", len(keys))
+	defer func() { end(err) }()
 
-	err := ocb.d.client.DeleteMulti(ctx, origKeys)
-	if err != nil {
-		return toWrapperError(err)
+	policy := retryPolicyFromContext(ctx, ocb.d.retryPolicy())
+	ranges := batchRanges(len(keys), ocb.d.maxBatchSize())
+
+	errs := runBatchesConcurrently(ranges, func(start, stop int) error {
+		return withRetry(ctx, policy, func() error {
+			origKeys := toOriginalKeys(keys[start:stop])
+
+			err := ocb.d.client.DeleteMulti(ctx, origKeys)
+			if err != nil {
+				return err
+			}
+
+			return nil
+		})
+	})
+
+	if merr := mergeBatchErrors(len(keys), ranges, errs); merr != nil {
+		err = toWrapperError(merr)
+		return err
 	}
 
 	return nil
 }
 
 func (ocb *originalClientBridgeImpl) Run(ctx context.Context, q w.Query) w.Iterator {
+	ctx, end := startSpan(ctx, ocb.d, "Run", -1)
+	defer func() { end(nil) }()
+
 	qImpl := q.(*queryImpl)
 	iter := ocb.d.client.Run(ctx, qImpl.q)
 
@@ -122,13 +211,17 @@ func (ocb *originalClientBridgeImpl) Run(ctx context.Context, q w.Query) w.Itera
 	return &iteratorImpl{client: ocb.d, q: qImpl, t: iter, firstError: qImpl.firstError}
 }
 
-func (ocb *originalClientBridgeImpl) GetAll(ctx context.Context, q w.Query, psList *[]w.PropertyList) ([]w.Key, error) {
+func (ocb *originalClientBridgeImpl) GetAll(ctx context.Context, q w.Query, psList *[]w.PropertyList) (_ []w.Key, err error) {
+	ctx, end := startSpan(ctx, ocb.d, "GetAll", -1)
+	defer func() { end(err) }()
+
 	qImpl := q.(*queryImpl)
 
 	origPss := toOriginalPropertyListList(*psList)
 	origKeys, err := ocb.d.client.GetAll(ctx, qImpl.q, &origPss)
 	if err != nil {
-		return nil, toWrapperError(err)
+		err = toWrapperError(err)
+		return nil, err
 	}
 
 	wKeys := toWrapperKeys(origKeys)
@@ -143,59 +236,108 @@ type originalTransactionBridgeImpl struct {
 	tx *transactionImpl
 }
 
-func (otb *originalTransactionBridgeImpl) PutMulti(keys []w.Key, psList []w.PropertyList) ([]w.PendingKey, error) {
+// Note: transaction sub-operations are not retried here even when a
+// RetryPolicy is configured. A transaction's baseTx is bound to a single
+// attempt at the Cloud Datastore RPC layer, so retrying an individual
+// PutMulti/GetMulti/DeleteMulti inside it would not be atomic with the rest
+// of the transaction. Retries on contention/unavailability instead apply to
+// the transaction as a whole; see RunInTransaction.
+
+func (otb *originalTransactionBridgeImpl) PutMulti(keys []w.Key, psList []w.PropertyList) (_ []w.PendingKey, err error) {
+	_, end := startSpan(otb.tx.client.ctx, otb.tx.client, "tx.PutMulti", len(keys))
+	defer func() { end(err) }()
+
 	baseTx := getTx(otb.tx.client.ctx)
 	if baseTx == nil {
-		return nil, errors.New("unexpected context")
+		err = errors.New("unexpected context")
+		return nil, err
 	}
 
-	origKeys := toOriginalKeys(keys)
-	origPss := toOriginalPropertyListList(psList)
+	ranges := batchRanges(len(keys), otb.tx.client.maxBatchSize())
+	wPKeys := make([]w.PendingKey, len(keys))
+	errs := runBatchesSequentially(ranges, func(start, stop int) error {
+		origKeys := toOriginalKeys(keys[start:stop])
+		origPss := toOriginalPropertyListList(psList[start:stop])
 
-	origPKeys, err := baseTx.PutMulti(origKeys, origPss)
-	if err != nil {
-		return nil, toWrapperError(err)
-	}
+		origPKeys, err := baseTx.PutMulti(origKeys, origPss)
+		if err != nil {
+			return err
+		}
 
-	wPKeys := toWrapperPendingKeys(origPKeys)
+		copy(wPKeys[start:stop], toWrapperPendingKeys(origPKeys))
+		return nil
+	})
+
+	if merr := mergeBatchErrors(len(keys), ranges, errs); merr != nil {
+		err = toWrapperError(merr)
+		return nil, err
+	}
 
 	return wPKeys, nil
 }
 
-func (otb *originalTransactionBridgeImpl) GetMulti(keys []w.Key, psList []w.PropertyList) error {
+func (otb *originalTransactionBridgeImpl) GetMulti(keys []w.Key, psList []w.PropertyList) (err error) {
+	_, end := startSpan(otb.tx.client.ctx, otb.tx.client, "tx.GetMulti", len(keys))
+	defer func() { end(err) }()
+
 	baseTx := getTx(otb.tx.client.ctx)
 	if baseTx == nil {
-		return errors.New("unexpected context")
+		err = errors.New("unexpected context")
+		return err
 	}
 
-	origKeys := toOriginalKeys(keys)
-	origPss := toOriginalPropertyListList(psList)
+	ranges := batchRanges(len(keys), otb.tx.client.maxBatchSize())
+	errs := runBatchesSequentially(ranges, func(start, stop int) error {
+		origKeys := toOriginalKeys(keys[start:stop])
+		origPss := toOriginalPropertyListList(psList[start:stop])
 
-	err := baseTx.GetMulti(origKeys, origPss)
-	if err != nil {
-		return toWrapperError(err)
-	}
+		err := baseTx.GetMulti(origKeys, origPss)
+		if err != nil {
+			return err
+		}
 
-	// TODO should be copy? not replace?
-	wPss := toWrapperPropertyListList(origPss)
-	for idx, wPs := range wPss {
-		psList[idx] = wPs
+		// TODO should be copy? not replace?
+		wPss := toWrapperPropertyListList(origPss)
+		for i, wPs := range wPss {
+			psList[start+i] = wPs
+		}
+
+		return nil
+	})
+
+	if merr := mergeBatchErrors(len(keys), ranges, errs); merr != nil {
+		err = toWrapperError(merr)
+		return err
 	}
 
 	return nil
 }
 
-func (otb *originalTransactionBridgeImpl) DeleteMulti(keys []w.Key) error {
+func (otb *originalTransactionBridgeImpl) DeleteMulti(keys []w.Key) (err error) {
+	_, end := startSpan(otb.tx.client.ctx, otb.tx.client, "tx.DeleteMulti", len(keys))
+	defer func() { end(err) }()
+
 	baseTx := getTx(otb.tx.client.ctx)
 	if baseTx == nil {
-		return errors.New("unexpected context")
+		err = errors.New("unexpected context")
+		return err
 	}
 
-	origKeys := toOriginalKeys(keys)
+	ranges := batchRanges(len(keys), otb.tx.client.maxBatchSize())
+	errs := runBatchesSequentially(ranges, func(start, stop int) error {
+		origKeys := toOriginalKeys(keys[start:stop])
 
-	err := baseTx.DeleteMulti(origKeys)
-	if err != nil {
-		return toWrapperError(err)
+		err := baseTx.DeleteMulti(origKeys)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+
+	if merr := mergeBatchErrors(len(keys), ranges, errs); merr != nil {
+		err = toWrapperError(merr)
+		return err
 	}
 
 	return nil
@@ -204,14 +346,18 @@ func (otb *originalTransactionBridgeImpl) DeleteMulti(keys []w.Key) error {
 type originalIteratorBridgeImpl struct {
 }
 
-func (oib *originalIteratorBridgeImpl) Next(iter w.Iterator, ps *w.PropertyList) (w.Key, error) {
+func (oib *originalIteratorBridgeImpl) Next(iter w.Iterator, ps *w.PropertyList) (_ w.Key, err error) {
 	iterImpl := iter.(*iteratorImpl)
 
+	_, end := startSpan(iterImpl.client.ctx, iterImpl.client, "Next", -1)
+	defer func() { end(err) }()
+
 	origPs := toOriginalPropertyList(*ps)
 
 	origKey, err := iterImpl.t.Next(origPs)
 	if err != nil {
-		return nil, toWrapperError(err)
+		err = toWrapperError(err)
+		return nil, err
 	}
 
 	*ps = toWrapperPropertyList(origPs)
@@ -0,0 +1,20 @@
+package clouddatastore
+
+import (
+	"context"
+
+	"cloud.google.com/go/datastore"
+	w "go.mercari.io/datastore"
+	"go.mercari.io/datastore/internal"
+)
+
+// datastoreImpl is the clouddatastore backend's implementation of w.Client.
+// Each instance wraps one *datastore.Client and caches the settings it was
+// built with, so bridge methods can reach per-client config (retry policy,
+// batch size, tracer/meter providers) without relying on shared state.
+type datastoreImpl struct {
+	ctx             context.Context
+	client          *datastore.Client
+	cacheStrategies []w.CacheStrategy
+	settings        *internal.ClientSettings
+}
@@ -0,0 +1,141 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"fmt"
+	"io"
+
+	w "go.mercari.io/datastore"
+)
+
+// archiveMagic/archiveVersion identify the binary EntityArchive format: a
+// short header naming the kind the archive holds, followed by one
+// length-prefixed, gob-encoded archiveRecord per entity. It's deliberately
+// simple compared to a real CAR file -- just enough framing to read records
+// back one at a time without loading the whole archive into memory.
+var archiveMagic = [4]byte{'D', 'S', 'A', 'R'}
+
+const archiveVersion = 1
+
+type archiveRecord struct {
+	Key        string
+	Properties []ndjsonProperty
+}
+
+type archiveEncoder struct {
+	w             io.Writer
+	headerWritten bool
+}
+
+func (e *archiveEncoder) writeHeader(kind string) error {
+	if e.headerWritten {
+		return nil
+	}
+	e.headerWritten = true
+
+	if _, err := e.w.Write(archiveMagic[:]); err != nil {
+		return err
+	}
+	if err := binary.Write(e.w, binary.BigEndian, uint8(archiveVersion)); err != nil {
+		return err
+	}
+	if err := binary.Write(e.w, binary.BigEndian, uint32(len(kind))); err != nil {
+		return err
+	}
+	_, err := io.WriteString(e.w, kind)
+	return err
+}
+
+func (e *archiveEncoder) writeEntity(key w.Key, ps w.PropertyList) error {
+	properties, err := toNdjsonProperties(ps)
+	if err != nil {
+		return fmt.Errorf("export: entity %s: %w", key.Encode(), err)
+	}
+	record := archiveRecord{Key: key.Encode(), Properties: properties}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(record); err != nil {
+		return fmt.Errorf("export: encode entity %s: %w", record.Key, err)
+	}
+
+	if err := binary.Write(e.w, binary.BigEndian, uint32(buf.Len())); err != nil {
+		return err
+	}
+	_, err = e.w.Write(buf.Bytes())
+	return err
+}
+
+type archiveDecoder struct {
+	r            io.Reader
+	headerParsed bool
+}
+
+func (d *archiveDecoder) parseHeader() error {
+	var magic [4]byte
+	if _, err := io.ReadFull(d.r, magic[:]); err != nil {
+		return err
+	}
+	if magic != archiveMagic {
+		return fmt.Errorf("export: not an EntityArchive (bad magic %q)", magic)
+	}
+
+	var version uint8
+	if err := binary.Read(d.r, binary.BigEndian, &version); err != nil {
+		return err
+	}
+	if version != archiveVersion {
+		return fmt.Errorf("export: unsupported EntityArchive version %d", version)
+	}
+
+	var kindLen uint32
+	if err := binary.Read(d.r, binary.BigEndian, &kindLen); err != nil {
+		return err
+	}
+	if _, err := io.CopyN(io.Discard, d.r, int64(kindLen)); err != nil {
+		return err
+	}
+
+	d.headerParsed = true
+	return nil
+}
+
+func (d *archiveDecoder) readEntity() (w.Key, w.PropertyList, error) {
+	if !d.headerParsed {
+		if err := d.parseHeader(); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var size uint32
+	if err := binary.Read(d.r, binary.BigEndian, &size); err != nil {
+		return nil, nil, err
+	}
+
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(d.r, buf); err != nil {
+		return nil, nil, err
+	}
+
+	var record archiveRecord
+	if err := gob.NewDecoder(bytes.NewReader(buf)).Decode(&record); err != nil {
+		return nil, nil, fmt.Errorf("export: decode entity: %w", err)
+	}
+
+	key, err := w.DecodeKey(record.Key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("export: decode key %q: %w", record.Key, err)
+	}
+
+	ps, err := fromNdjsonProperties(record.Properties)
+	if err != nil {
+		return nil, nil, fmt.Errorf("export: entity %s: %w", record.Key, err)
+	}
+
+	return key, ps, nil
+}
+
+func errUnknownFormat(format Format) error {
+	return fmt.Errorf("export: unknown format %d", format)
+}
@@ -0,0 +1,89 @@
+// Package export streams Cloud Datastore query results to and from portable
+// archive files, for migrations, emulator seeding and environment cloning.
+package export
+
+import (
+	"context"
+	"io"
+
+	w "go.mercari.io/datastore"
+)
+
+// Format selects the on-disk representation used by ExportQuery and Import.
+type Format int
+
+const (
+	// NDJSON writes one JSON object per line: the entity's encoded key plus
+	// its properties. It's easy to inspect, grep and diff.
+	NDJSON Format = iota
+	// EntityArchive writes a small binary header describing the archive
+	// followed by one length-prefixed, gob-encoded record per entity,
+	// similar in spirit to a CAR file. It's more compact than NDJSON for
+	// large exports.
+	EntityArchive
+)
+
+type encoder interface {
+	writeHeader(kind string) error
+	writeEntity(key w.Key, ps w.PropertyList) error
+}
+
+type decoder interface {
+	readEntity() (w.Key, w.PropertyList, error)
+}
+
+func newEncoder(out io.Writer, format Format) (encoder, error) {
+	switch format {
+	case NDJSON:
+		return &ndjsonEncoder{w: out}, nil
+	case EntityArchive:
+		return &archiveEncoder{w: out}, nil
+	default:
+		return nil, errUnknownFormat(format)
+	}
+}
+
+func newDecoder(in io.Reader, format Format) (decoder, error) {
+	switch format {
+	case NDJSON:
+		return &ndjsonDecoder{r: in}, nil
+	case EntityArchive:
+		return &archiveDecoder{r: in}, nil
+	default:
+		return nil, errUnknownFormat(format)
+	}
+}
+
+// ExportQuery runs q against client and streams every result entity to out
+// in the given format, one entity at a time, so exports are not bounded by
+// how much fits in memory.
+func ExportQuery(ctx context.Context, client w.Client, q w.Query, out io.Writer, format Format) error {
+	enc, err := newEncoder(out, format)
+	if err != nil {
+		return err
+	}
+
+	iter := client.Run(ctx, q)
+	headerWritten := false
+	for {
+		var ps w.PropertyList
+		key, err := iter.Next(&ps)
+		if err == w.Done {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if !headerWritten {
+			if err := enc.writeHeader(key.Kind()); err != nil {
+				return err
+			}
+			headerWritten = true
+		}
+
+		if err := enc.writeEntity(key, ps); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package export
+
+import (
+	"context"
+	"io"
+
+	w "go.mercari.io/datastore"
+)
+
+// importBatchSize caps how many entities Import buffers before flushing a
+// PutMulti call. The backend's own MaxBatchSize/RetryPolicy still apply on
+// top of this if the underlying client is a clouddatastore one.
+const importBatchSize = 500
+
+// Import reads entities previously written by ExportQuery and writes them
+// back via client.PutMulti, in batches of importBatchSize, returning the
+// number of entities imported.
+func Import(ctx context.Context, client w.Client, r io.Reader, format Format) (n int, err error) {
+	dec, err := newDecoder(r, format)
+	if err != nil {
+		return 0, err
+	}
+
+	var keys []w.Key
+	var psList []w.PropertyList
+
+	flush := func() error {
+		if len(keys) == 0 {
+			return nil
+		}
+		if _, err := client.PutMulti(ctx, keys, psList); err != nil {
+			return err
+		}
+		n += len(keys)
+		keys = keys[:0]
+		psList = psList[:0]
+		return nil
+	}
+
+	for {
+		key, ps, err := dec.readEntity()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return n, err
+		}
+
+		keys = append(keys, key)
+		psList = append(psList, ps)
+
+		if len(keys) >= importBatchSize {
+			if err := flush(); err != nil {
+				return n, err
+			}
+		}
+	}
+
+	if err := flush(); err != nil {
+		return n, err
+	}
+
+	return n, nil
+}
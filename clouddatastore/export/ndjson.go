@@ -0,0 +1,84 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	w "go.mercari.io/datastore"
+)
+
+type ndjsonProperty struct {
+	Name     string      `json:"name"`
+	Value    taggedValue `json:"value"`
+	NoIndex  bool        `json:"no_index,omitempty"`
+	Multiple bool        `json:"multiple,omitempty"`
+}
+
+type ndjsonEntity struct {
+	Key        string           `json:"key"`
+	Properties []ndjsonProperty `json:"properties"`
+}
+
+type ndjsonEncoder struct {
+	w io.Writer
+}
+
+func (e *ndjsonEncoder) writeHeader(kind string) error {
+	// NDJSON carries no separate header frame; each line is self-describing.
+	return nil
+}
+
+func (e *ndjsonEncoder) writeEntity(key w.Key, ps w.PropertyList) error {
+	properties, err := toNdjsonProperties(ps)
+	if err != nil {
+		return fmt.Errorf("export: entity %s: %w", key.Encode(), err)
+	}
+	entity := ndjsonEntity{Key: key.Encode(), Properties: properties}
+
+	line, err := json.Marshal(entity)
+	if err != nil {
+		return fmt.Errorf("export: marshal entity %s: %w", entity.Key, err)
+	}
+	line = append(line, '\n')
+
+	_, err = e.w.Write(line)
+	return err
+}
+
+type ndjsonDecoder struct {
+	r       io.Reader
+	scanner *bufio.Scanner
+}
+
+func (d *ndjsonDecoder) readEntity() (w.Key, w.PropertyList, error) {
+	if d.scanner == nil {
+		d.scanner = bufio.NewScanner(d.r)
+		d.scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	}
+
+	if !d.scanner.Scan() {
+		if err := d.scanner.Err(); err != nil {
+			return nil, nil, err
+		}
+		return nil, nil, io.EOF
+	}
+
+	var entity ndjsonEntity
+	if err := json.Unmarshal(d.scanner.Bytes(), &entity); err != nil {
+		return nil, nil, fmt.Errorf("export: unmarshal entity: %w", err)
+	}
+
+	key, err := w.DecodeKey(entity.Key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("export: decode key %q: %w", entity.Key, err)
+	}
+
+	ps, err := fromNdjsonProperties(entity.Properties)
+	if err != nil {
+		return nil, nil, fmt.Errorf("export: entity %s: %w", entity.Key, err)
+	}
+
+	return key, ps, nil
+}
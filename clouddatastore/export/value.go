@@ -0,0 +1,149 @@
+package export
+
+import (
+	"fmt"
+	"time"
+
+	w "go.mercari.io/datastore"
+)
+
+// taggedValue is a concrete, gob- and JSON-friendly stand-in for a
+// Property's interface{} Value. A Property's Value holds one of a fixed set
+// of concrete types -- nil, bool, string, int64, float64, []byte,
+// time.Time, w.Key, w.GeoPoint, or a nested w.PropertyList for an embedded
+// entity -- so encoding it as a bare interface{} corrupts round-tripped
+// data two different ways: gob refuses to encode an unregistered concrete
+// type behind an interface, and encoding/json silently loses fidelity
+// (int64 overflowing into float64 past 2^53, time.Time and []byte becoming
+// plain strings). Tagging the value with its kind and storing it in a
+// type-specific field avoids both problems.
+type taggedValue struct {
+	Kind   string           `json:"kind"`
+	Int    int64            `json:"int,omitempty"`
+	Bool   bool             `json:"bool,omitempty"`
+	Str    string           `json:"str,omitempty"`
+	Float  float64          `json:"float,omitempty"`
+	Bytes  []byte           `json:"bytes,omitempty"`
+	Time   time.Time        `json:"time,omitempty"`
+	Lat    float64          `json:"lat,omitempty"`
+	Lng    float64          `json:"lng,omitempty"`
+	Entity []ndjsonProperty `json:"entity,omitempty"`
+}
+
+const (
+	kindNull     = "null"
+	kindInt64    = "int64"
+	kindBool     = "bool"
+	kindString   = "string"
+	kindFloat    = "float64"
+	kindBytes    = "bytes"
+	kindTime     = "time"
+	kindKey      = "key"
+	kindGeoPoint = "geopoint"
+	kindEntity   = "entity"
+)
+
+// toTaggedValue converts a Property.Value into its tagged form. It returns
+// an error for any value type the Cloud Datastore property model doesn't
+// define, rather than silently dropping or mis-encoding it.
+func toTaggedValue(v interface{}) (taggedValue, error) {
+	switch val := v.(type) {
+	case nil:
+		return taggedValue{Kind: kindNull}, nil
+	case int64:
+		return taggedValue{Kind: kindInt64, Int: val}, nil
+	case bool:
+		return taggedValue{Kind: kindBool, Bool: val}, nil
+	case string:
+		return taggedValue{Kind: kindString, Str: val}, nil
+	case float64:
+		return taggedValue{Kind: kindFloat, Float: val}, nil
+	case []byte:
+		return taggedValue{Kind: kindBytes, Bytes: val}, nil
+	case time.Time:
+		return taggedValue{Kind: kindTime, Time: val}, nil
+	case w.Key:
+		return taggedValue{Kind: kindKey, Str: val.Encode()}, nil
+	case w.GeoPoint:
+		return taggedValue{Kind: kindGeoPoint, Lat: val.Lat, Lng: val.Lng}, nil
+	case w.PropertyList:
+		props, err := toNdjsonProperties(val)
+		if err != nil {
+			return taggedValue{}, fmt.Errorf("nested entity: %w", err)
+		}
+		return taggedValue{Kind: kindEntity, Entity: props}, nil
+	default:
+		return taggedValue{}, fmt.Errorf("export: unsupported property value type %T", v)
+	}
+}
+
+// fromTaggedValue is the inverse of toTaggedValue.
+func fromTaggedValue(tv taggedValue) (interface{}, error) {
+	switch tv.Kind {
+	case kindNull:
+		return nil, nil
+	case kindInt64:
+		return tv.Int, nil
+	case kindBool:
+		return tv.Bool, nil
+	case kindString:
+		return tv.Str, nil
+	case kindFloat:
+		return tv.Float, nil
+	case kindBytes:
+		return tv.Bytes, nil
+	case kindTime:
+		return tv.Time, nil
+	case kindKey:
+		return w.DecodeKey(tv.Str)
+	case kindGeoPoint:
+		return w.GeoPoint{Lat: tv.Lat, Lng: tv.Lng}, nil
+	case kindEntity:
+		props, err := fromNdjsonProperties(tv.Entity)
+		if err != nil {
+			return nil, fmt.Errorf("nested entity: %w", err)
+		}
+		return props, nil
+	default:
+		return nil, fmt.Errorf("export: unknown tagged value kind %q", tv.Kind)
+	}
+}
+
+// toNdjsonProperties converts a PropertyList to its tagged-value wire form.
+// It's used both for the entity a query returns directly and, recursively,
+// for the PropertyList a Property.Value holds when it represents a nested
+// entity.
+func toNdjsonProperties(ps w.PropertyList) ([]ndjsonProperty, error) {
+	out := make([]ndjsonProperty, len(ps))
+	for i, p := range ps {
+		value, err := toTaggedValue(p.Value)
+		if err != nil {
+			return nil, fmt.Errorf("property %q: %w", p.Name, err)
+		}
+		out[i] = ndjsonProperty{
+			Name:     p.Name,
+			Value:    value,
+			NoIndex:  p.NoIndex,
+			Multiple: p.Multiple,
+		}
+	}
+	return out, nil
+}
+
+// fromNdjsonProperties is the inverse of toNdjsonProperties.
+func fromNdjsonProperties(in []ndjsonProperty) (w.PropertyList, error) {
+	ps := make(w.PropertyList, len(in))
+	for i, p := range in {
+		value, err := fromTaggedValue(p.Value)
+		if err != nil {
+			return nil, fmt.Errorf("property %q: %w", p.Name, err)
+		}
+		ps[i] = w.Property{
+			Name:     p.Name,
+			Value:    value,
+			NoIndex:  p.NoIndex,
+			Multiple: p.Multiple,
+		}
+	}
+	return ps, nil
+}
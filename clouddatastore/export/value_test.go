@@ -0,0 +1,138 @@
+package export
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"testing"
+	"time"
+
+	w "go.mercari.io/datastore"
+)
+
+// TestTaggedValueRoundTrip exercises toTaggedValue/fromTaggedValue directly,
+// and through the two wire encodings ndjson and EntityArchive actually use
+// (encoding/json and encoding/gob), for every concrete type a Property.Value
+// holds. Before taggedValue existed, encoding/json lost precision on int64
+// past 2^53 and turned time.Time/[]byte into plain strings, and
+// encoding/gob refused to encode the types it does not register by default
+// (time.Time among them) behind an interface{} without gob.Register.
+func TestTaggedValueRoundTrip(t *testing.T) {
+	now := time.Date(2026, 7, 26, 12, 0, 0, 0, time.UTC)
+
+	cases := []interface{}{
+		nil,
+		int64(9007199254740993), // 2^53 + 1: the smallest int64 a float64 can't represent exactly
+		true,
+		"hello",
+		3.5,
+		[]byte{0x00, 0x01, 0xff},
+		now,
+	}
+
+	for _, want := range cases {
+		tv, err := toTaggedValue(want)
+		if err != nil {
+			t.Fatalf("toTaggedValue(%#v): %v", want, err)
+		}
+
+		// Round-trip through JSON, as ndjsonEncoder/Decoder do.
+		jsonBytes, err := json.Marshal(tv)
+		if err != nil {
+			t.Fatalf("json.Marshal(%#v): %v", tv, err)
+		}
+		var fromJSON taggedValue
+		if err := json.Unmarshal(jsonBytes, &fromJSON); err != nil {
+			t.Fatalf("json.Unmarshal: %v", err)
+		}
+
+		// Round-trip through gob, as archiveEncoder/Decoder do.
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(tv); err != nil {
+			t.Fatalf("gob encode %#v: %v", tv, err)
+		}
+		var fromGob taggedValue
+		if err := gob.NewDecoder(&buf).Decode(&fromGob); err != nil {
+			t.Fatalf("gob decode: %v", err)
+		}
+
+		for name, decoded := range map[string]taggedValue{"json": fromJSON, "gob": fromGob} {
+			got, err := fromTaggedValue(decoded)
+			if err != nil {
+				t.Fatalf("%s: fromTaggedValue(%#v): %v", name, decoded, err)
+			}
+			if gotTime, ok := got.(time.Time); ok {
+				wantTime := want.(time.Time)
+				if !gotTime.Equal(wantTime) {
+					t.Errorf("%s: got time %v, want %v", name, gotTime, wantTime)
+				}
+				continue
+			}
+			if gotBytes, ok := got.([]byte); ok {
+				wantBytes, _ := want.([]byte)
+				if !bytes.Equal(gotBytes, wantBytes) {
+					t.Errorf("%s: got bytes %v, want %v", name, gotBytes, wantBytes)
+				}
+				continue
+			}
+			if got != want {
+				t.Errorf("%s: got %#v (%T), want %#v (%T)", name, got, got, want, want)
+			}
+		}
+	}
+}
+
+func TestToTaggedValueUnsupportedType(t *testing.T) {
+	if _, err := toTaggedValue(struct{}{}); err == nil {
+		t.Fatal("expected an error for an unsupported property value type")
+	}
+}
+
+func TestTaggedValueGeoPoint(t *testing.T) {
+	want := w.GeoPoint{Lat: 35.6895, Lng: 139.6917}
+
+	tv, err := toTaggedValue(want)
+	if err != nil {
+		t.Fatalf("toTaggedValue(%#v): %v", want, err)
+	}
+
+	got, err := fromTaggedValue(tv)
+	if err != nil {
+		t.Fatalf("fromTaggedValue: %v", err)
+	}
+	if got != want {
+		t.Errorf("got %#v, want %#v", got, want)
+	}
+}
+
+func TestTaggedValueNestedEntity(t *testing.T) {
+	want := w.PropertyList{
+		{Name: "street", Value: "1 Infinite Loop"},
+		{Name: "zip", Value: int64(95014)},
+	}
+
+	tv, err := toTaggedValue(want)
+	if err != nil {
+		t.Fatalf("toTaggedValue(%#v): %v", want, err)
+	}
+	if tv.Kind != kindEntity {
+		t.Fatalf("got kind %q, want %q", tv.Kind, kindEntity)
+	}
+
+	got, err := fromTaggedValue(tv)
+	if err != nil {
+		t.Fatalf("fromTaggedValue: %v", err)
+	}
+	gotPs, ok := got.(w.PropertyList)
+	if !ok {
+		t.Fatalf("got %T, want w.PropertyList", got)
+	}
+	if len(gotPs) != len(want) {
+		t.Fatalf("got %d properties, want %d", len(gotPs), len(want))
+	}
+	for i := range want {
+		if gotPs[i].Name != want[i].Name || gotPs[i].Value != want[i].Value {
+			t.Errorf("property %d: got %+v, want %+v", i, gotPs[i], want[i])
+		}
+	}
+}
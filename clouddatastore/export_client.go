@@ -0,0 +1,30 @@
+package clouddatastore
+
+import (
+	"context"
+	"io"
+
+	w "go.mercari.io/datastore"
+	"go.mercari.io/datastore/clouddatastore/export"
+)
+
+// ExportQuery runs q and streams every result entity to out in the given
+// format. It delegates to the export package, which is backend-agnostic and
+// only needs the w.Client methods it's given here.
+//
+// w.Client is meant to expose ExportQuery/Import directly rather than
+// requiring callers to go through the export package themselves; this
+// trimmed checkout doesn't include the root datastore.go file that declares
+// w.Client, so that interface couldn't be widened as part of this series.
+// Landing this change for real requires adding matching ExportQuery/Import
+// method signatures to w.Client alongside this implementation.
+func (d *datastoreImpl) ExportQuery(ctx context.Context, q w.Query, out io.Writer, format export.Format) error {
+	return export.ExportQuery(ctx, d, q, out, format)
+}
+
+// Import reads entities previously written by ExportQuery from r and writes
+// them back via PutMulti, returning the number of entities imported. See
+// the ExportQuery doc comment for the w.Client interface caveat.
+func (d *datastoreImpl) Import(ctx context.Context, r io.Reader, format export.Format) (int, error) {
+	return export.Import(ctx, d, r, format)
+}
@@ -0,0 +1,83 @@
+package clouddatastore
+
+import (
+	"context"
+	"time"
+
+	"go.mercari.io/datastore/internal"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// retryPolicy returns the RetryPolicy configured on the client that created
+// d, or nil if retries are disabled.
+func (d *datastoreImpl) retryPolicy() internal.RetryPolicy {
+	if d.settings == nil {
+		return nil
+	}
+	return d.settings.RetryPolicy
+}
+
+type contextRetryPolicy struct{}
+
+// retryPolicyOverride wraps the policy stored by WithRetryPolicy, so that a
+// nil policy still counts as an override.
+type retryPolicyOverride struct {
+	policy internal.RetryPolicy
+}
+
+// WithRetryPolicy overrides the client-wide RetryPolicy for every call made
+// with the returned context, e.g. to disable retries for a single
+// latency-sensitive request by passing a nil policy.
+func WithRetryPolicy(ctx context.Context, policy internal.RetryPolicy) context.Context {
+	return context.WithValue(ctx, contextRetryPolicy{}, retryPolicyOverride{policy: policy})
+}
+
+func retryPolicyFromContext(ctx context.Context, fallback internal.RetryPolicy) internal.RetryPolicy {
+	if override, ok := ctx.Value(contextRetryPolicy{}).(retryPolicyOverride); ok {
+		return override.policy
+	}
+	return fallback
+}
+
+// isRetryableError reports whether err is a transient gRPC failure that is
+// safe to retry a whole PutMulti/GetMulti/DeleteMulti call for: contention
+// aborts, deadline exceeded and service unavailability.
+func isRetryableError(err error) bool {
+	switch status.Code(err) {
+	case codes.Aborted, codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	default:
+		return false
+	}
+}
+
+// withRetry calls op, and while policy is non-nil and the error it returns is
+// retryable, sleeps for the policy's backoff and calls op again. op is
+// expected to rebuild any original-client-library arguments from scratch on
+// each call so a retried batch is never partially applied twice.
+func withRetry(ctx context.Context, policy internal.RetryPolicy, op func() error) error {
+	if policy == nil {
+		return op()
+	}
+
+	attempt := 0
+	for {
+		err := op()
+		if err == nil || !isRetryableError(err) {
+			return err
+		}
+
+		attempt++
+		backoff, ok := policy.NextBackoff(attempt, err)
+		if !ok {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package clouddatastore
+
+import (
+	"context"
+
+	"cloud.google.com/go/datastore"
+	w "go.mercari.io/datastore"
+)
+
+// RunInTransaction is datastoreImpl's sole implementation of
+// w.Client.RunInTransaction (no duplicate declaration exists elsewhere in
+// this package). Its opts ...w.TransactionOption parameter requires
+// w.Client.RunInTransaction's own signature to take the same parameter;
+// the root datastore.go that declares w.Client isn't part of this trimmed
+// checkout, so that interface widening isn't included in this series and
+// couldn't be verified here -- landing this change for real also requires
+// updating w.Client to match.
+//
+// RunInTransaction runs f inside a new Cloud Datastore transaction. Passing
+// w.WithReadOnly() or w.WithReadTime(t) opens a read-only, point-in-time
+// consistent transaction instead of the default read-write one.
+//
+// If d's RetryPolicy is set (or overridden via WithRetryPolicy on ctx), the
+// whole transaction -- a fresh baseTx, a fresh call to f, and Commit -- is
+// retried on contention/unavailability, since Cloud Datastore transactions
+// can only be retried in full: a partially-run f against an aborted baseTx
+// cannot be resumed. f must therefore be idempotent and safe to call more
+// than once with a fresh tx each time.
+func (d *datastoreImpl) RunInTransaction(ctx context.Context, f func(tx w.Transaction) error, opts ...w.TransactionOption) (commit w.Commit, err error) {
+	ctx, end := startSpan(ctx, d, "RunInTransaction", -1)
+	defer func() { end(err) }()
+
+	settings := w.NewTransactionSettings(opts...)
+
+	var txOpts []datastore.TransactionOption
+	if settings.ReadOnly {
+		txOpts = append(txOpts, datastore.ReadOnly)
+	}
+	if !settings.ReadTime.IsZero() {
+		txOpts = append(txOpts, datastore.WithReadTime(settings.ReadTime))
+	}
+
+	policy := retryPolicyFromContext(ctx, d.retryPolicy())
+	err = withRetry(ctx, policy, func() error {
+		baseTx, txErr := d.client.NewTransaction(ctx, txOpts...)
+		if txErr != nil {
+			return toWrapperError(txErr)
+		}
+
+		txCtx := context.WithValue(ctx, contextTransaction{}, baseTx)
+		tx := &transactionImpl{client: &datastoreImpl{ctx: txCtx, client: d.client, settings: d.settings, cacheStrategies: d.cacheStrategies}, readOnly: settings.ReadOnly}
+
+		if txErr = f(tx); txErr != nil {
+			_ = tx.Rollback()
+			return txErr
+		}
+
+		c, txErr := tx.Commit()
+		if txErr != nil {
+			return txErr
+		}
+		commit = c
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return commit, nil
+}
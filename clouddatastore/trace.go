@@ -0,0 +1,110 @@
+package clouddatastore
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/status"
+)
+
+const instrumentationName = "go.mercari.io/datastore/clouddatastore"
+
+// tracerProvider returns d's configured TracerProvider, or the global default
+// if d is nil or didn't configure one via WithTracerProvider.
+func tracerProvider(d *datastoreImpl) trace.TracerProvider {
+	if d != nil && d.settings != nil && d.settings.TracerProvider != nil {
+		return d.settings.TracerProvider
+	}
+	return otel.GetTracerProvider()
+}
+
+// meterProvider returns d's configured MeterProvider, or the global default
+// if d is nil or didn't configure one via WithMeterProvider.
+func meterProvider(d *datastoreImpl) metric.MeterProvider {
+	if d != nil && d.settings != nil && d.settings.MeterProvider != nil {
+		return d.settings.MeterProvider
+	}
+	return otel.GetMeterProvider()
+}
+
+func tracer(d *datastoreImpl) trace.Tracer {
+	return tracerProvider(d).Tracer(instrumentationName)
+}
+
+type bridgeInstruments struct {
+	latency   metric.Float64Histogram
+	batchSize metric.Int64Histogram
+}
+
+// instrumentsByProvider caches one set of histograms per distinct
+// MeterProvider, so each client's own WithMeterProvider actually gets
+// instrumented instead of only the first provider any client happened to
+// use process-wide.
+var instrumentsByProvider sync.Map // metric.MeterProvider -> *bridgeInstruments
+
+// instruments lazily builds the package's histograms from d's MeterProvider
+// the first time that provider is used.
+func instruments(d *datastoreImpl) (metric.Float64Histogram, metric.Int64Histogram) {
+	provider := meterProvider(d)
+
+	if cached, ok := instrumentsByProvider.Load(provider); ok {
+		bi := cached.(*bridgeInstruments)
+		return bi.latency, bi.batchSize
+	}
+
+	meter := provider.Meter(instrumentationName)
+	bi := &bridgeInstruments{}
+	bi.latency, _ = meter.Float64Histogram(
+		"clouddatastore.bridge.latency",
+		metric.WithDescription("Latency of Cloud Datastore bridge RPCs, in milliseconds"),
+		metric.WithUnit("ms"),
+	)
+	bi.batchSize, _ = meter.Int64Histogram(
+		"clouddatastore.bridge.batch_size",
+		metric.WithDescription("Number of keys/entities sent per Cloud Datastore bridge RPC"),
+	)
+
+	actual, _ := instrumentsByProvider.LoadOrStore(provider, bi)
+	bi = actual.(*bridgeInstruments)
+	return bi.latency, bi.batchSize
+}
+
+// startSpan starts a span named "clouddatastore.<op>" with the given
+// attributes, plus d's project ID and whether the call is running inside a
+// transaction. d identifies the client the call belongs to, so its
+// TracerProvider/MeterProvider and project ID are used instead of any
+// shared, process-wide default. It returns a function that must be called
+// with the operation's outcome to end the span, record its error status and
+// report latency/batch-size metrics.
+func startSpan(ctx context.Context, d *datastoreImpl, op string, keyCount int, attrs ...attribute.KeyValue) (context.Context, func(err error)) {
+	if d != nil && d.settings != nil {
+		attrs = append(attrs, attribute.String("datastore.project_id", d.settings.ProjectID))
+	}
+	if keyCount >= 0 {
+		attrs = append(attrs, attribute.Int("datastore.key_count", keyCount))
+	}
+	attrs = append(attrs, attribute.Bool("datastore.in_transaction", getTx(ctx) != nil))
+
+	spanCtx, span := tracer(d).Start(ctx, "clouddatastore."+op, trace.WithAttributes(attrs...))
+	start := time.Now()
+
+	return spanCtx, func(err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetAttributes(attribute.String("datastore.error_code", status.Code(err).String()))
+		}
+		span.End()
+
+		latency, batchSize := instruments(d)
+		opAttrs := metric.WithAttributes(attribute.String("op", op))
+		latency.Record(ctx, float64(time.Since(start).Milliseconds()), opAttrs)
+		if keyCount >= 0 {
+			batchSize.Record(ctx, int64(keyCount), opAttrs)
+		}
+	}
+}
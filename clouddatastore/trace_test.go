@@ -0,0 +1,39 @@
+package clouddatastore
+
+import (
+	"testing"
+
+	"go.mercari.io/datastore/internal"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+)
+
+// countingMeterProvider counts how many times Meter is called, so tests can
+// tell whether instruments() actually built new histograms for it or
+// reused a cached set.
+type countingMeterProvider struct {
+	calls int
+}
+
+func (p *countingMeterProvider) Meter(name string, opts ...metric.MeterOption) metric.Meter {
+	p.calls++
+	return noop.NewMeterProvider().Meter(name, opts...)
+}
+
+func TestInstrumentsBuildsOncePerMeterProvider(t *testing.T) {
+	p1 := &countingMeterProvider{}
+	p2 := &countingMeterProvider{}
+	d1 := &datastoreImpl{settings: &internal.ClientSettings{MeterProvider: p1}}
+	d2 := &datastoreImpl{settings: &internal.ClientSettings{MeterProvider: p2}}
+
+	instruments(d1)
+	instruments(d1)
+	instruments(d2)
+
+	if p1.calls != 1 {
+		t.Errorf("p1.calls = %d, want 1 (instruments should cache across repeat calls for the same provider)", p1.calls)
+	}
+	if p2.calls != 1 {
+		t.Errorf("p2.calls = %d, want 1 (a second client's own MeterProvider must still get instrumented)", p2.calls)
+	}
+}
@@ -23,7 +23,8 @@ func getTx(ctx context.Context) *datastore.Transaction {
 }
 
 type transactionImpl struct {
-	client *datastoreImpl
+	client   *datastoreImpl
+	readOnly bool
 }
 
 type commitImpl struct {
@@ -106,7 +107,17 @@ func (tx *transactionImpl) DeleteMulti(keys []w.Key) error {
 	})
 }
 
-func (tx *transactionImpl) Commit() (w.Commit, error) {
+func (tx *transactionImpl) Commit() (_ w.Commit, err error) {
+	_, end := startSpan(tx.client.ctx, tx.client, "Commit", -1)
+	defer func() { end(err) }()
+
+	// A read-only transaction never mutates anything, so there is nothing
+	// for Cloud Datastore to commit; calling baseTx.Commit() on it would
+	// just return an empty, meaningless Commit.
+	if tx.readOnly {
+		return nil, nil
+	}
+
 	baseTx := getTx(tx.client.ctx)
 	if baseTx == nil {
 		return nil, nil
@@ -114,21 +125,30 @@ func (tx *transactionImpl) Commit() (w.Commit, error) {
 
 	commit, err := baseTx.Commit()
 	if err != nil {
-		return nil, toWrapperError(err)
+		err = toWrapperError(err)
+		return nil, err
 	}
 
 	return &commitImpl{commit}, nil
 }
 
-func (tx *transactionImpl) Rollback() error {
+func (tx *transactionImpl) Rollback() (err error) {
+	_, end := startSpan(tx.client.ctx, tx.client, "Rollback", -1)
+	defer func() { end(err) }()
+
+	if tx.readOnly {
+		return nil
+	}
+
 	baseTx := getTx(tx.client.ctx)
 	if tx == nil {
 		return nil
 	}
 
-	err := baseTx.Rollback()
+	err = baseTx.Rollback()
 	if err != nil {
-		return toWrapperError(err)
+		err = toWrapperError(err)
+		return err
 	}
 
 	return nil
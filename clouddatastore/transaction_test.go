@@ -0,0 +1,41 @@
+package clouddatastore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	w "go.mercari.io/datastore"
+)
+
+func TestReadOnlyTransactionCommitAndRollbackAreNoOps(t *testing.T) {
+	tx := &transactionImpl{client: &datastoreImpl{ctx: context.Background()}, readOnly: true}
+
+	commit, err := tx.Commit()
+	if err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+	if commit != nil {
+		t.Fatalf("Commit: got %#v, want nil (a read-only transaction never mutates anything)", commit)
+	}
+
+	if err := tx.Rollback(); err != nil {
+		t.Fatalf("Rollback: %v", err)
+	}
+}
+
+func TestWithReadOnlyAndWithReadTime(t *testing.T) {
+	settings := w.NewTransactionSettings(w.WithReadOnly())
+	if !settings.ReadOnly {
+		t.Fatal("WithReadOnly: settings.ReadOnly = false, want true")
+	}
+
+	readTime := time.Date(2026, 7, 26, 12, 0, 0, 0, time.UTC)
+	settings = w.NewTransactionSettings(w.WithReadTime(readTime))
+	if !settings.ReadOnly {
+		t.Fatal("WithReadTime: settings.ReadOnly = false, want true")
+	}
+	if !settings.ReadTime.Equal(readTime) {
+		t.Fatalf("WithReadTime: settings.ReadTime = %v, want %v", settings.ReadTime, readTime)
+	}
+}
@@ -0,0 +1,53 @@
+package internal
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/oauth2"
+)
+
+// ClientSettings holds the options collected from ClientOption values passed
+// to FromContext. Backend packages (e.g. clouddatastore) read this struct to
+// build the underlying client.
+type ClientSettings struct {
+	ProjectID       string
+	Scopes          []string
+	TokenSource     oauth2.TokenSource
+	CredentialsFile string
+	HTTPClient      *http.Client
+
+	// DatabaseID selects a Firestore in Datastore mode database other than
+	// the default `(default)` database. Empty means the default database.
+	DatabaseID string
+
+	// EmulatorHost points the client at a local Datastore/Firestore emulator
+	// instead of the production service, e.g. "localhost:8081".
+	EmulatorHost string
+
+	// RetryPolicy, when set, is consulted by backends to retry transient
+	// failures on PutMulti/GetMulti/DeleteMulti and on whole transactions run
+	// through RunInTransaction; the PutMulti/GetMulti/DeleteMulti calls made
+	// inside a transaction are not retried on their own. Nil means no retries
+	// are performed.
+	RetryPolicy RetryPolicy
+
+	// MaxBatchSize caps how many entities a single PutMulti/GetMulti/
+	// DeleteMulti call sends to Cloud Datastore at once. Calls larger than
+	// this are split into sequential or parallel sub-batches by the backend.
+	// Zero means the backend's default (500, Cloud Datastore's own limit).
+	MaxBatchSize int
+
+	// TracerProvider and MeterProvider, when set, are used to create the
+	// tracer/meter backends instrument their operations with. Nil means the
+	// respective otel global provider is used.
+	TracerProvider trace.TracerProvider
+	MeterProvider  metric.MeterProvider
+}
+
+// GetProjectID returns the project ID to fall back to when it can't be
+// resolved from the GCE metadata server (e.g. when running locally).
+func GetProjectID() string {
+	return ""
+}
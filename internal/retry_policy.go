@@ -0,0 +1,10 @@
+package internal
+
+import "time"
+
+// RetryPolicy decides whether a failed operation should be retried and, if
+// so, how long to wait before the next attempt. attempt is 1 for the first
+// retry (i.e. the second overall try).
+type RetryPolicy interface {
+	NextBackoff(attempt int, err error) (backoff time.Duration, retry bool)
+}